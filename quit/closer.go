@@ -0,0 +1,68 @@
+package quit
+
+import (
+	"context"
+	"sync"
+)
+
+// Closer is a lightweight per-subsystem cancellation primitive, modeled on
+// dgraph's ristretto/z.Closer. It pairs a cancelable context with a
+// WaitGroup so a background worker can select on Ctx() to know when to
+// stop, while its owner can block on Wait() until it actually has.
+//
+// A Closer can be registered with a QuitEvent via RegisterCloser, since it
+// implements io.Closer: GracefulStop will then call SignalAndWait on it
+// alongside the event's other registered closers.
+type Closer struct {
+	waiting sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// NewCloser returns a ready-to-use Closer whose WaitGroup starts at
+// initial, e.g. the number of goroutines the caller is about to start.
+func NewCloser(initial int) *Closer {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Closer{ctx: ctx, cancel: cancel}
+	c.waiting.Add(initial)
+	return c
+}
+
+// Ctx returns the context that is canceled when Signal is called.
+func (c *Closer) Ctx() context.Context {
+	return c.ctx
+}
+
+// AddRunning adds delta to the count of goroutines Wait blocks on.
+func (c *Closer) AddRunning(delta int) {
+	c.waiting.Add(delta)
+}
+
+// Done marks one goroutine tracked by this Closer as finished.
+func (c *Closer) Done() {
+	c.waiting.Done()
+}
+
+// Signal cancels Ctx, telling goroutines selecting on it to stop.
+func (c *Closer) Signal() {
+	c.cancel()
+}
+
+// Wait blocks until every goroutine tracked by this Closer has called Done.
+func (c *Closer) Wait() {
+	c.waiting.Wait()
+}
+
+// SignalAndWait cancels Ctx and blocks until every tracked goroutine has
+// called Done.
+func (c *Closer) SignalAndWait() {
+	c.Signal()
+	c.Wait()
+}
+
+// Close implements io.Closer by calling SignalAndWait, so a Closer can be
+// registered directly with QuitEvent.RegisterCloser.
+func (c *Closer) Close() error {
+	c.SignalAndWait()
+	return nil
+}
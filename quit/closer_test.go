@@ -0,0 +1,61 @@
+package quit
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestCloserSignalAndWait(t *testing.T) {
+	c := NewCloser(1)
+	workerDone := make(chan struct{})
+
+	go func() {
+		<-c.Ctx().Done()
+		close(workerDone)
+		c.Done()
+	}()
+
+	c.SignalAndWait()
+
+	select {
+	case <-workerDone:
+	default:
+		t.Fatal("worker goroutine did not observe Ctx cancellation before SignalAndWait returned")
+	}
+}
+
+func TestCloserAddRunning(t *testing.T) {
+	c := NewCloser(0)
+	c.AddRunning(2)
+
+	go c.Done()
+	go c.Done()
+
+	waitCh := make(chan struct{})
+	go func() {
+		c.Wait()
+		close(waitCh)
+	}()
+
+	select {
+	case <-waitCh:
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return after both tracked goroutines called Done")
+	}
+}
+
+func TestCloserClose(t *testing.T) {
+	c := NewCloser(0)
+
+	var closer io.Closer = c
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	select {
+	case <-c.Ctx().Done():
+	default:
+		t.Fatal("Close did not cancel Ctx")
+	}
+}
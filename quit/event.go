@@ -0,0 +1,38 @@
+package quit
+
+import "sync"
+
+// Event is a one-shot broadcast signal: Fire closes its channel exactly
+// once, and any number of goroutines can select on Done to observe it.
+type Event struct {
+	once sync.Once
+	done chan struct{}
+}
+
+// NewEvent returns a ready-to-use Event.
+func NewEvent() *Event {
+	return &Event{done: make(chan struct{})}
+}
+
+// Fire closes the event's channel exactly once, notifying every goroutine
+// selecting on Done.
+func (e *Event) Fire() {
+	e.once.Do(func() {
+		close(e.done)
+	})
+}
+
+// Done returns a channel that is closed once Fire has been called.
+func (e *Event) Done() <-chan struct{} {
+	return e.done
+}
+
+// Fired reports whether Fire has been called.
+func (e *Event) Fired() bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,28 @@
+package quit
+
+import "testing"
+
+func TestEventFireIsIdempotent(t *testing.T) {
+	e := NewEvent()
+
+	select {
+	case <-e.Done():
+		t.Fatal("Done is closed before Fire was called")
+	default:
+	}
+	if e.Fired() {
+		t.Fatal("Fired reports true before Fire was called")
+	}
+
+	e.Fire()
+	e.Fire() // must not panic on double close
+
+	select {
+	case <-e.Done():
+	default:
+		t.Fatal("Done is not closed after Fire was called")
+	}
+	if !e.Fired() {
+		t.Fatal("Fired reports false after Fire was called")
+	}
+}
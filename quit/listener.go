@@ -0,0 +1,129 @@
+package quit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// GracefulListener wraps a net.Listener, tracking every accepted connection
+// against a QuitEvent's goroutine count so GracefulStop can block until all
+// in-flight connections have closed. If the QuitEvent's Hammer phase fires
+// first, e.g. because a client is holding an idle keep-alive connection
+// open, every connection still tracked is force-closed instead.
+type GracefulListener struct {
+	net.Listener
+	quit *QuitEvent
+
+	mu    sync.Mutex
+	conns map[*gracefulConn]struct{}
+}
+
+// NewGracefulListener wraps l, tracking connections against the global
+// QuitEvent.
+func NewGracefulListener(l net.Listener) *GracefulListener {
+	return NewGracefulListenerWithQuitEvent(l, GetQuitEvent())
+}
+
+// NewGracefulListenerWithQuitEvent wraps l, tracking connections against q.
+func NewGracefulListenerWithQuitEvent(l net.Listener, q *QuitEvent) *GracefulListener {
+	gl := &GracefulListener{Listener: l, quit: q, conns: make(map[*gracefulConn]struct{})}
+	go gl.hammerOnSignal()
+	return gl
+}
+
+// hammerOnSignal force-closes every connection still tracked once the
+// QuitEvent's Hammer phase fires, so a lingering idle connection can't keep
+// GracefulStop waiting on WaitGoroutines past the grace period.
+func (gl *GracefulListener) hammerOnSignal() {
+	<-gl.quit.HammerChan()
+
+	gl.mu.Lock()
+	conns := make([]*gracefulConn, 0, len(gl.conns))
+	for c := range gl.conns {
+		conns = append(conns, c)
+	}
+	gl.mu.Unlock()
+
+	for _, c := range conns {
+		_ = c.Close()
+	}
+}
+
+// Accept accepts the next connection and counts it as a running goroutine
+// until it is closed.
+func (gl *GracefulListener) Accept() (net.Conn, error) {
+	conn, err := gl.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	gl.quit.AddGoroutine()
+	gc := &gracefulConn{Conn: conn, listener: gl}
+	gl.track(gc)
+	return gc, nil
+}
+
+// Shutdown implements QuitCloser by closing the underlying listener, which
+// stops accepting new connections without affecting connections already in
+// flight.
+func (gl *GracefulListener) Shutdown(_ context.Context) error {
+	return gl.Listener.Close()
+}
+
+func (gl *GracefulListener) track(c *gracefulConn) {
+	gl.mu.Lock()
+	gl.conns[c] = struct{}{}
+	gl.mu.Unlock()
+}
+
+func (gl *GracefulListener) untrack(c *gracefulConn) {
+	gl.mu.Lock()
+	delete(gl.conns, c)
+	gl.mu.Unlock()
+}
+
+// gracefulConn decrements its listener's QuitEvent goroutine count exactly
+// once, when the connection is closed, and untracks itself so the listener
+// no longer force-closes it on Hammer.
+type gracefulConn struct {
+	net.Conn
+	listener  *GracefulListener
+	closeOnce sync.Once
+}
+
+// Close closes the underlying connection and marks it done with its
+// listener's QuitEvent.
+func (c *gracefulConn) Close() error {
+	err := c.Conn.Close()
+	c.closeOnce.Do(func() {
+		c.listener.untrack(c)
+		c.listener.quit.DoneGoroutine()
+	})
+	return err
+}
+
+// ListenAndServe listens on addr and serves handler through a
+// GracefulListener registered with the global QuitEvent, so GracefulStop
+// stops accepting new connections but waits for in-flight requests to
+// complete before returning.
+func ListenAndServe(addr string, handler http.Handler) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return Serve(l, handler)
+}
+
+// Serve serves handler on l through a GracefulListener registered with the
+// global QuitEvent, so GracefulStop stops accepting new connections but
+// waits for in-flight requests to complete before returning. The
+// http.Server itself, not the listener, is registered as the QuitCloser so
+// Serve returns the idiomatic http.ErrServerClosed on shutdown.
+func Serve(l net.Listener, handler http.Handler) error {
+	gl := NewGracefulListener(l)
+	srv := &http.Server{Handler: handler}
+	gl.quit.RegisterQuitCloser(srv)
+
+	return srv.Serve(gl)
+}
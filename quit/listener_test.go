@@ -0,0 +1,104 @@
+package quit
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestGracefulListenerTracksConnections(t *testing.T) {
+	rawListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+
+	q := NewQuitEvent()
+	gl := NewGracefulListenerWithQuitEvent(rawListener, q)
+
+	acceptedConn := make(chan net.Conn, 1)
+	go func() {
+		conn, err := gl.Accept()
+		if err != nil {
+			return
+		}
+		acceptedConn <- conn
+	}()
+
+	clientConn, err := net.Dial("tcp", rawListener.Addr().String())
+	if err != nil {
+		t.Fatalf("net.Dial: %v", err)
+	}
+	defer clientConn.Close()
+
+	var serverConn net.Conn
+	select {
+	case serverConn = <-acceptedConn:
+	case <-time.After(time.Second):
+		t.Fatal("Accept never returned a connection")
+	}
+
+	waitDone := make(chan struct{})
+	go func() {
+		q.WaitGoroutines()
+		close(waitDone)
+	}()
+
+	select {
+	case <-waitDone:
+		t.Fatal("WaitGoroutines returned before the accepted connection was closed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := serverConn.Close(); err != nil {
+		t.Fatalf("closing accepted connection: %v", err)
+	}
+
+	select {
+	case <-waitDone:
+	case <-time.After(time.Second):
+		t.Fatal("WaitGoroutines did not return after the accepted connection was closed")
+	}
+}
+
+func TestGracefulListenerHammerForceClosesLingeringConnections(t *testing.T) {
+	rawListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+
+	q := NewQuitEvent()
+	gl := NewGracefulListenerWithQuitEvent(rawListener, q)
+
+	accepted := make(chan struct{})
+	go func() {
+		_, _ = gl.Accept()
+		close(accepted)
+	}()
+
+	clientConn, err := net.Dial("tcp", rawListener.Addr().String())
+	if err != nil {
+		t.Fatalf("net.Dial: %v", err)
+	}
+	defer clientConn.Close()
+
+	select {
+	case <-accepted:
+	case <-time.After(time.Second):
+		t.Fatal("Accept never returned a connection")
+	}
+
+	waitDone := make(chan struct{})
+	go func() {
+		q.WaitGoroutines()
+		close(waitDone)
+	}()
+
+	q.GracefulStop(context.Background(), 10*time.Millisecond)
+
+	select {
+	case <-waitDone:
+	case <-time.After(time.Second):
+		t.Fatal("Hammer did not force-close the lingering connection, WaitGoroutines never returned")
+	}
+}
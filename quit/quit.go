@@ -2,10 +2,12 @@ package quit
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/signal"
+	"sort"
 	"sync"
 	"syscall"
 	"time"
@@ -16,6 +18,26 @@ var (
 	once       sync.Once
 )
 
+// Priority controls the order in which registered closers run during
+// GracefulStop: higher priorities are closed first. Closers sharing a
+// priority are closed concurrently.
+const (
+	// PriorityFirst is closed before everything else, e.g. HTTP servers
+	// that must stop accepting requests before their dependencies go away.
+	PriorityFirst uint8 = 255
+	// PriorityNormal is the default priority used by RegisterCloser and
+	// RegisterQuitCloser for backward compatibility.
+	PriorityNormal uint8 = 128
+	// PriorityLast is closed after everything else, e.g. database pools
+	// that other closers may still depend on while shutting down.
+	PriorityLast uint8 = 0
+)
+
+// defaultBucketTimeout bounds how long a single priority bucket may take to
+// close when GracefulStop is called with both a gracePeriod <= 0 and a ctx
+// carrying no deadline of its own.
+const defaultBucketTimeout = 10 * time.Second
+
 // init
 func init() {
 	gQuitEvent = NewQuitEvent()
@@ -34,14 +56,50 @@ func GetQuitEvent() *QuitEvent {
 // QuitEvent quit event struct
 type QuitEvent struct {
 	*Event
-	// quit closer list to be close
-	quitCloserList []QuitCloser
-	// io closer list to be close
-	closerList []io.Closer
+	// quit closer list to be close, grouped by priority
+	quitCloserList []priorityQuitCloser
+	// io closer list to be close, grouped by priority
+	closerList []priorityCloser
 	// stop func list
 	stopFuncList []func()
 	// counts active goroutines for GracefulStop
 	serveWG sync.WaitGroup
+	// errHandler receives the aggregated GracefulStop error, if set.
+	errHandler func(error)
+
+	// shutdown/hammer/terminate/done phase channels, each closed exactly once.
+	shutdownChan  chan struct{}
+	hammerChan    chan struct{}
+	terminateChan chan struct{}
+	doneChan      chan struct{}
+
+	shutdownOnce  sync.Once
+	hammerOnce    sync.Once
+	terminateOnce sync.Once
+	doneOnce      sync.Once
+
+	// hammerCtx is canceled when the Hammer phase fires, so in-flight work
+	// selecting on it can abort instead of waiting out the grace period.
+	hammerCtx    context.Context
+	hammerCancel context.CancelFunc
+
+	// hammerHookList and terminateHookList run when their phase fires.
+	hammerHookList    []func()
+	terminateHookList []func()
+	// terminateWG tracks terminate hooks so Terminate can wait for them.
+	terminateWG sync.WaitGroup
+}
+
+// priorityQuitCloser pairs a QuitCloser with the priority it was registered at.
+type priorityQuitCloser struct {
+	priority uint8
+	closer   QuitCloser
+}
+
+// priorityCloser pairs an io.Closer with the priority it was registered at.
+type priorityCloser struct {
+	priority uint8
+	closer   io.Closer
 }
 
 // QuitCloser Shutdown
@@ -53,11 +111,111 @@ type QuitCloser interface {
 
 // NewQuitEvent returns a new, ready-to-use Event.
 func NewQuitEvent() *QuitEvent {
+	hammerCtx, hammerCancel := context.WithCancel(context.Background())
 	return &QuitEvent{
-		Event: NewEvent(),
+		Event:         NewEvent(),
+		shutdownChan:  make(chan struct{}),
+		hammerChan:    make(chan struct{}),
+		terminateChan: make(chan struct{}),
+		doneChan:      make(chan struct{}),
+		hammerCtx:     hammerCtx,
+		hammerCancel:  hammerCancel,
 	}
 }
 
+// ShutdownChan returns a channel that is closed when the Shutdown phase
+// fires, i.e. as soon as GracefulStop is called.
+func (q *QuitEvent) ShutdownChan() <-chan struct{} {
+	return q.shutdownChan
+}
+
+// HammerChan returns a channel that is closed when the Hammer phase fires,
+// i.e. when the grace period passed to GracefulStop elapses without all
+// goroutines finishing, or when WaitSignal catches a second stop signal.
+func (q *QuitEvent) HammerChan() <-chan struct{} {
+	return q.hammerChan
+}
+
+// TerminateChan returns a channel that is closed when the Terminate phase
+// fires, after WaitGoroutines has returned.
+func (q *QuitEvent) TerminateChan() <-chan struct{} {
+	return q.terminateChan
+}
+
+// DoneChan returns a channel that is closed once GracefulStop has finished
+// running all terminate hooks.
+func (q *QuitEvent) DoneChan() <-chan struct{} {
+	return q.doneChan
+}
+
+// HammerContext returns a context that is canceled when the Hammer phase
+// fires, so in-flight work can select on it to abort early instead of
+// blocking until the process exits.
+func (q *QuitEvent) HammerContext() context.Context {
+	return q.hammerCtx
+}
+
+// RegisterHammerHook registers a func to run when the Hammer phase fires.
+func (q *QuitEvent) RegisterHammerHook(hook func()) {
+	q.hammerHookList = append(q.hammerHookList, hook)
+}
+
+// RegisterTerminateHook registers a func to run when the Terminate phase
+// fires. GracefulStop waits for all terminate hooks to return before
+// entering the Done phase.
+func (q *QuitEvent) RegisterTerminateHook(hook func()) {
+	q.terminateHookList = append(q.terminateHookList, hook)
+}
+
+// fireShutdown fires the Shutdown phase exactly once.
+func (q *QuitEvent) fireShutdown() {
+	q.shutdownOnce.Do(func() {
+		q.Fire()
+		close(q.shutdownChan)
+	})
+}
+
+// fireHammer fires the Hammer phase exactly once, canceling HammerContext
+// and running any registered hammer hooks.
+func (q *QuitEvent) fireHammer() {
+	q.hammerOnce.Do(func() {
+		close(q.hammerChan)
+		q.hammerCancel()
+		for _, hook := range q.hammerHookList {
+			if hook != nil {
+				hook()
+			}
+		}
+	})
+}
+
+// fireTerminate fires the Terminate phase exactly once, running any
+// registered terminate hooks and waiting for them to finish.
+func (q *QuitEvent) fireTerminate() {
+	q.terminateOnce.Do(func() {
+		close(q.terminateChan)
+		for _, hook := range q.terminateHookList {
+			if hook == nil {
+				continue
+			}
+			hook := hook
+			q.terminateWG.Add(1)
+			go func() {
+				defer q.terminateWG.Done()
+				hook()
+			}()
+		}
+		q.terminateWG.Wait()
+	})
+}
+
+// fireDone fires the Done phase exactly once.
+func (q *QuitEvent) fireDone() {
+	q.doneOnce.Do(func() {
+		close(q.doneChan)
+	})
+}
+
 // AddGoroutine Incr count of running goroutine
 func (q *QuitEvent) AddGoroutine() {
 	q.serveWG.Add(1)
@@ -73,14 +231,28 @@ func (q *QuitEvent) WaitGoroutines() {
 	q.serveWG.Wait()
 }
 
-// RegisterQuitCloser closer will be called before goroutine quit.
+// RegisterQuitCloser closer will be called before goroutine quit, at PriorityNormal.
 func (q *QuitEvent) RegisterQuitCloser(closer QuitCloser) {
-	q.quitCloserList = append(q.quitCloserList, closer)
+	q.RegisterQuitCloserWithPriority(PriorityNormal, closer)
+}
+
+// RegisterQuitCloserWithPriority registers closer to be called before goroutine
+// quit. Closers are grouped by priority and run from PriorityFirst down to
+// PriorityLast; closers sharing a priority run concurrently.
+func (q *QuitEvent) RegisterQuitCloserWithPriority(priority uint8, closer QuitCloser) {
+	q.quitCloserList = append(q.quitCloserList, priorityQuitCloser{priority: priority, closer: closer})
 }
 
-// RegisterCloser closer will be called before goroutine quit.
+// RegisterCloser closer will be called before goroutine quit, at PriorityNormal.
 func (q *QuitEvent) RegisterCloser(closer io.Closer) {
-	q.closerList = append(q.closerList, closer)
+	q.RegisterCloserWithPriority(PriorityNormal, closer)
+}
+
+// RegisterCloserWithPriority registers closer to be called before goroutine
+// quit. Closers are grouped by priority and run from PriorityFirst down to
+// PriorityLast; closers sharing a priority run concurrently.
+func (q *QuitEvent) RegisterCloserWithPriority(priority uint8, closer io.Closer) {
+	q.closerList = append(q.closerList, priorityCloser{priority: priority, closer: closer})
 }
 
 // RegisterStopFunc stop func will be called before goroutine quit.
@@ -88,42 +260,176 @@ func (q *QuitEvent) RegisterStopFunc(stopFunc func()) {
 	q.stopFuncList = append(q.stopFuncList, stopFunc)
 }
 
-// GracefulStop Graceful stop all running goroutines.
-func (q *QuitEvent) GracefulStop() {
-	q.Fire()
-	for _, closer := range q.quitCloserList {
-		if closer != nil {
-			_ = closer.Shutdown(context.TODO())
-		}
-	}
-	for _, closer := range q.closerList {
-		if closer != nil {
-			_ = closer.Close()
-		}
+// SetErrHandler registers a handler that receives the aggregated error
+// returned by GracefulStop, so errors from individual closers are no longer
+// silently dropped.
+func (q *QuitEvent) SetErrHandler(handler func(error)) {
+	q.errHandler = handler
+}
+
+// GracefulStop drives the QuitEvent through its Shutdown, Hammer, Terminate
+// and Done phases. It fires Shutdown, closes registered closers grouped by
+// priority (closers sharing a priority run concurrently, bounded by a
+// per-bucket timeout derived from ctx and gracePeriod), then waits up to
+// gracePeriod for WaitGoroutines. If goroutines are still running when
+// gracePeriod elapses, it fires Hammer to cancel in-flight work via
+// HammerContext before continuing to wait. Finally it fires Terminate,
+// running registered terminate hooks to completion, and Done. Errors from
+// individual closers are aggregated with errors.Join, returned, and (if
+// set) passed to the err handler.
+func (q *QuitEvent) GracefulStop(ctx context.Context, gracePeriod time.Duration) error {
+	q.fireShutdown()
+
+	var errs []error
+	for _, priority := range q.closePriorities() {
+		errs = append(errs, q.closeBucket(ctx, priority, gracePeriod)...)
 	}
+
 	for _, stopFunc := range q.stopFuncList {
 		if stopFunc != nil {
 			stopFunc()
 		}
 	}
-	q.WaitGoroutines()
+
+	waitDone := make(chan struct{})
+	go func() {
+		q.WaitGoroutines()
+		close(waitDone)
+	}()
+	select {
+	case <-waitDone:
+	case <-time.After(gracePeriod):
+		q.fireHammer()
+		<-waitDone
+	}
+
+	q.fireTerminate()
+	q.fireDone()
+
+	err := errors.Join(errs...)
+	if err != nil && q.errHandler != nil {
+		q.errHandler(err)
+	}
+	return err
+}
+
+// closePriorities returns the distinct priorities in use, highest first.
+func (q *QuitEvent) closePriorities() []uint8 {
+	seen := make(map[uint8]struct{})
+	for _, c := range q.quitCloserList {
+		seen[c.priority] = struct{}{}
+	}
+	for _, c := range q.closerList {
+		seen[c.priority] = struct{}{}
+	}
+
+	priorities := make([]uint8, 0, len(seen))
+	for p := range seen {
+		priorities = append(priorities, p)
+	}
+	sort.Slice(priorities, func(i, j int) bool { return priorities[i] > priorities[j] })
+	return priorities
+}
+
+// closeBucket closes every closer registered at priority, concurrently. If
+// ctx carries no deadline of its own, the bucket is bounded by gracePeriod
+// instead of an independent default: a QuitCloser such as an http.Server is
+// expected to take up to gracePeriod to drain in-flight work as part of a
+// perfectly normal shutdown, and shouldn't be timed out sooner than that.
+func (q *QuitEvent) closeBucket(ctx context.Context, priority uint8, gracePeriod time.Duration) []error {
+	bucketCtx := ctx
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		timeout := gracePeriod
+		if timeout <= 0 {
+			timeout = defaultBucketTimeout
+		}
+		var cancel context.CancelFunc
+		bucketCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+
+	collect := func(err error) {
+		if err == nil {
+			return
+		}
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+
+	for _, c := range q.quitCloserList {
+		if c.priority != priority || c.closer == nil {
+			continue
+		}
+		closer := c.closer
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			collect(closer.Shutdown(bucketCtx))
+		}()
+	}
+	for _, c := range q.closerList {
+		if c.priority != priority || c.closer == nil {
+			continue
+		}
+		closer := c.closer
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			collect(closer.Close())
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-bucketCtx.Done():
+		// io.Closer.Close takes no context, so a closer that ignores
+		// bucketCtx (or simply hangs) cannot be interrupted here; we stop
+		// waiting on it so GracefulStop can still make progress, at the
+		// cost of leaking the goroutine closing it.
+		collect(fmt.Errorf("quit: priority %d closers timed out: %w", priority, bucketCtx.Err()))
+	}
+
+	return errs
 }
 
-// WaitSignal stop signal handle
-func WaitSignal(waitSecond int) {
-	shutdownHook := make(chan os.Signal, 1)
-	signal.Notify(shutdownHook,
+// WaitSignal blocks until a stop signal is caught, then drives the global
+// QuitEvent through GracefulStop with the given grace period. A second
+// SIGINT/SIGTERM/SIGQUIT received while Shutdown is still in progress fires
+// Hammer immediately instead of waiting out the rest of the grace period.
+// WaitSignal does not itself watch for SIGHUP; call WatchHangup alongside
+// it if the process should restart via a Restarter on SIGHUP.
+func WaitSignal(gracePeriod time.Duration) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh,
 		syscall.SIGINT,
 		syscall.SIGTERM,
 		syscall.SIGQUIT,
 		os.Interrupt)
-	sig := <-shutdownHook
-
+	sig := <-sigCh
 	fmt.Printf("caught sig exit sig:%v\n", sig)
+
+	q := GetQuitEvent()
+	done := make(chan struct{})
 	go func() {
-		GetQuitEvent().GracefulStop()
+		_ = q.GracefulStop(context.Background(), gracePeriod)
+		close(done)
 	}()
-	// wait 3 second for quit event graceful stop.
-	time.Sleep(time.Duration(waitSecond) * time.Second)
-	os.Exit(0)
+
+	select {
+	case <-done:
+	case sig = <-sigCh:
+		fmt.Printf("caught second sig:%v, hammering shutdown\n", sig)
+		q.fireHammer()
+		<-done
+	}
 }
@@ -0,0 +1,154 @@
+package quit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeCloser struct {
+	closeFn func() error
+}
+
+func (f *fakeCloser) Close() error {
+	if f.closeFn != nil {
+		return f.closeFn()
+	}
+	return nil
+}
+
+type fakeQuitCloser struct {
+	shutdownFn func(ctx context.Context) error
+}
+
+func (f *fakeQuitCloser) Shutdown(ctx context.Context) error {
+	if f.shutdownFn != nil {
+		return f.shutdownFn(ctx)
+	}
+	return nil
+}
+
+func TestGracefulStopOrdersClosersByPriority(t *testing.T) {
+	q := NewQuitEvent()
+
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) {
+		mu.Lock()
+		order = append(order, name)
+		mu.Unlock()
+	}
+
+	q.RegisterCloserWithPriority(PriorityLast, &fakeCloser{closeFn: func() error { record("last"); return nil }})
+	q.RegisterCloserWithPriority(PriorityFirst, &fakeCloser{closeFn: func() error { record("first"); return nil }})
+	q.RegisterCloser(&fakeCloser{closeFn: func() error { record("normal"); return nil }})
+
+	if err := q.GracefulStop(context.Background(), time.Second); err != nil {
+		t.Fatalf("GracefulStop returned unexpected error: %v", err)
+	}
+
+	if got := []string{order[0], order[1], order[2]}; got[0] != "first" || got[1] != "normal" || got[2] != "last" {
+		t.Fatalf("closers ran out of priority order: %v", order)
+	}
+}
+
+func TestGracefulStopAggregatesErrors(t *testing.T) {
+	q := NewQuitEvent()
+
+	errA := errors.New("closer a failed")
+	errB := errors.New("closer b failed")
+	q.RegisterCloser(&fakeCloser{closeFn: func() error { return errA }})
+	q.RegisterQuitCloser(&fakeQuitCloser{shutdownFn: func(context.Context) error { return errB }})
+
+	var handled error
+	q.SetErrHandler(func(err error) { handled = err })
+
+	err := q.GracefulStop(context.Background(), time.Second)
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatalf("GracefulStop error does not wrap both closer errors: %v", err)
+	}
+	if !errors.Is(handled, errA) || !errors.Is(handled, errB) {
+		t.Fatalf("err handler was not called with the aggregated error: %v", handled)
+	}
+}
+
+func TestCloseBucketTimesOutOnHungCloser(t *testing.T) {
+	q := NewQuitEvent()
+
+	release := make(chan struct{})
+	q.RegisterCloser(&fakeCloser{closeFn: func() error {
+		<-release
+		return nil
+	}})
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- q.GracefulStop(ctx, time.Second)
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected a timeout error from the hung closer, got nil")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("GracefulStop did not return once its bucket context expired; a hung closer blocked it")
+	}
+}
+
+func TestGracefulStopDoesNotTimeOutCloserWithinGracePeriod(t *testing.T) {
+	q := NewQuitEvent()
+
+	// A closer that takes a noticeable while to finish, but well within a
+	// generous gracePeriod, must not be reported as timed out:
+	// a QuitCloser like an http.Server draining slow in-flight requests is a
+	// perfectly normal shutdown, not a hang.
+	q.RegisterQuitCloser(&fakeQuitCloser{shutdownFn: func(ctx context.Context) error {
+		select {
+		case <-time.After(300 * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}})
+
+	var handled error
+	q.SetErrHandler(func(err error) { handled = err })
+
+	if err := q.GracefulStop(context.Background(), time.Second); err != nil {
+		t.Fatalf("GracefulStop returned unexpected error for a closer well within gracePeriod: %v", err)
+	}
+	if handled != nil {
+		t.Fatalf("err handler was called despite a fully graceful shutdown: %v", handled)
+	}
+}
+
+func TestGracefulStopFiresHammerAfterGracePeriod(t *testing.T) {
+	q := NewQuitEvent()
+	q.AddGoroutine() // never released, forcing the grace period to elapse
+
+	done := make(chan error, 1)
+	go func() {
+		done <- q.GracefulStop(context.Background(), 10*time.Millisecond)
+	}()
+
+	select {
+	case <-q.HammerChan():
+	case <-time.After(time.Second):
+		t.Fatal("Hammer phase never fired after the grace period elapsed")
+	}
+
+	q.DoneGoroutine()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("GracefulStop did not return after the leftover goroutine finished")
+	}
+}
@@ -0,0 +1,42 @@
+package quit
+
+import (
+	"net"
+	"sync"
+)
+
+// Environment variables used to pass inherited listener file descriptors,
+// and the readiness handshake descriptor, across a zero-downtime restart.
+const (
+	// EnvListenFDs holds the number of listener file descriptors inherited
+	// by this process, starting at fd 3. Matches the systemd convention so
+	// existing tooling that sets LISTEN_FDS keeps working.
+	EnvListenFDs = "LISTEN_FDS"
+	// EnvGoutilsListenFDs is an alias for EnvListenFDs, set by Restarter.Fork
+	// so a restarted process doesn't need systemd socket activation to pick
+	// up inherited listeners.
+	EnvGoutilsListenFDs = "GOUTILS_LISTEN_FDS"
+)
+
+var (
+	gRestarter    *Restarter
+	restarterOnce sync.Once
+)
+
+// GetRestarter returns the global Restarter, creating it on first use.
+func GetRestarter() *Restarter {
+	restarterOnce.Do(func() {
+		if gRestarter == nil {
+			gRestarter = NewRestarter()
+		}
+	})
+	return gRestarter
+}
+
+// ListenTCP returns a TCP listener for addr via the global Restarter. If
+// this process inherited listener file descriptors from a previous
+// instance (see Restarter.Fork), the listener is reconstructed from the
+// inherited descriptor instead of binding a new socket.
+func ListenTCP(network, addr string) (*net.TCPListener, error) {
+	return GetRestarter().ListenTCP(network, addr)
+}
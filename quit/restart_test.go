@@ -0,0 +1,30 @@
+package quit
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestGetRestarterIsSingleton(t *testing.T) {
+	var wg sync.WaitGroup
+	restarters := make([]*Restarter, 32)
+
+	for i := range restarters {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			restarters[i] = GetRestarter()
+		}(i)
+	}
+	wg.Wait()
+
+	first := restarters[0]
+	if first == nil {
+		t.Fatal("GetRestarter returned nil")
+	}
+	for i, r := range restarters {
+		if r != first {
+			t.Fatalf("GetRestarter returned a different instance at index %d, singleton not enforced", i)
+		}
+	}
+}
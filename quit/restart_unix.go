@@ -0,0 +1,240 @@
+//go:build !windows
+
+package quit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// listenerFDOffset is the first file descriptor number inherited listeners
+// are placed at; 0, 1 and 2 are stdin/stdout/stderr.
+const listenerFDOffset = 3
+
+// envReadyFD names the env var that tells a forked child which file
+// descriptor to write its readiness byte to.
+const envReadyFD = "GOUTILS_READY_FD"
+
+// DefaultRestartGracePeriod is the grace period Fork passes to GracefulStop
+// once the child has signaled it is ready to serve.
+const DefaultRestartGracePeriod = 30 * time.Second
+
+// Restarter supports zero-downtime binary upgrades by forking the current
+// binary, handing it the listeners this process is already serving on via
+// inherited file descriptors, and gracefully stopping this process once
+// the child signals it is ready to take over. Fork itself does not listen
+// for SIGHUP; call WatchHangup to trigger it on SIGHUP, or call Fork
+// directly on whatever trigger the caller prefers.
+type Restarter struct {
+	mu        sync.Mutex
+	listeners []*net.TCPListener
+}
+
+// NewRestarter returns a ready-to-use Restarter.
+func NewRestarter() *Restarter {
+	return &Restarter{}
+}
+
+// WatchHangup starts a goroutine that calls r.Fork every time this process
+// receives SIGHUP, performing a zero-downtime restart. It returns
+// immediately; call it once at startup, typically alongside WaitSignal.
+// Errors from Fork are not returned to the caller since they arrive
+// asynchronously on a signal; they are printed to stdout instead.
+func WatchHangup(r *Restarter) {
+	hangupCh := make(chan os.Signal, 1)
+	signal.Notify(hangupCh, syscall.SIGHUP)
+	go func() {
+		for range hangupCh {
+			if err := r.Fork(); err != nil {
+				fmt.Printf("quit: restart on SIGHUP failed: %v\n", err)
+			}
+		}
+	}()
+}
+
+// inheritedListenerCount reports how many listener file descriptors this
+// process inherited, per EnvListenFDs/EnvGoutilsListenFDs.
+func inheritedListenerCount() int {
+	for _, env := range []string{EnvGoutilsListenFDs, EnvListenFDs} {
+		if n, err := strconv.Atoi(os.Getenv(env)); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// ListenTCP returns a TCP listener for addr. If this process inherited
+// listener file descriptors from a prior instance (see Fork), the next
+// inherited descriptor is reconstructed into a listener instead of binding
+// a new socket; callers must call ListenTCP in the same order every run so
+// inherited descriptors line up with the listeners that produced them.
+func (r *Restarter) ListenTCP(network, addr string) (*net.TCPListener, error) {
+	r.mu.Lock()
+	index := len(r.listeners)
+	r.mu.Unlock()
+
+	if index < inheritedListenerCount() {
+		f := os.NewFile(uintptr(listenerFDOffset+index), fmt.Sprintf("listener-%d", index))
+		l, err := net.FileListener(f)
+		_ = f.Close()
+		if err == nil {
+			if tcpListener, ok := l.(*net.TCPListener); ok {
+				r.track(tcpListener)
+				return tcpListener, nil
+			}
+			_ = l.Close()
+		}
+		// Fall through and bind fresh if the inherited fd couldn't be reused.
+	}
+
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	tcpListener, ok := l.(*net.TCPListener)
+	if !ok {
+		_ = l.Close()
+		return nil, fmt.Errorf("quit: %s listener is not a *net.TCPListener", network)
+	}
+	r.track(tcpListener)
+	return tcpListener, nil
+}
+
+func (r *Restarter) track(l *net.TCPListener) {
+	r.mu.Lock()
+	r.listeners = append(r.listeners, l)
+	r.mu.Unlock()
+}
+
+// Fork execs a copy of the current binary, passing it the tracked listeners
+// as inherited file descriptors, waits for the child to signal readiness
+// over a Unix socket handshake, then calls GracefulStop on this process so
+// the new binary can take over without dropping connections.
+func (r *Restarter) Fork() error {
+	r.mu.Lock()
+	listeners := append([]*net.TCPListener(nil), r.listeners...)
+	r.mu.Unlock()
+
+	files := make([]*os.File, 0, len(listeners))
+	for _, l := range listeners {
+		f, err := l.File()
+		if err != nil {
+			return fmt.Errorf("quit: dup listener fd: %w", err)
+		}
+		files = append(files, f)
+	}
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+
+	parentConn, childSock, err := socketpair()
+	if err != nil {
+		return fmt.Errorf("quit: create readiness socket pair: %w", err)
+	}
+	defer func() { _ = parentConn.Close() }()
+
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("quit: resolve executable path: %w", err)
+	}
+
+	readyFD := listenerFDOffset + len(files)
+	cmd := exec.Command(execPath, os.Args[1:]...)
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("%s=%d", EnvListenFDs, len(files)),
+		fmt.Sprintf("%s=%d", EnvGoutilsListenFDs, len(files)),
+		fmt.Sprintf("%s=%d", envReadyFD, readyFD))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = append(files, childSock)
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("quit: start child process: %w", err)
+	}
+	_ = childSock.Close()
+
+	if err := waitForReadyTimeout(parentConn, DefaultRestartGracePeriod); err != nil {
+		return fmt.Errorf("quit: child did not signal readiness: %w", err)
+	}
+
+	return GetQuitEvent().GracefulStop(context.Background(), DefaultRestartGracePeriod)
+}
+
+// SignalReady writes a readiness byte to the file descriptor named by
+// envReadyFD, if this process was started by Restarter.Fork. Call it once
+// the forked process is ready to accept connections on its inherited
+// listeners; it is a no-op otherwise.
+func SignalReady() error {
+	fdStr := os.Getenv(envReadyFD)
+	if fdStr == "" {
+		return nil
+	}
+	fd, err := strconv.Atoi(fdStr)
+	if err != nil {
+		return fmt.Errorf("quit: invalid %s: %w", envReadyFD, err)
+	}
+	f := os.NewFile(uintptr(fd), "ready")
+	defer func() { _ = f.Close() }()
+	_, err = f.Write([]byte{1})
+	return err
+}
+
+// socketpair creates a connected pair of Unix sockets used for the
+// readiness handshake between a parent process and the child it forks. The
+// parent's end is wrapped as a *net.UnixConn, registering it with the
+// runtime's network poller so SetReadDeadline actually works on it; a raw
+// *os.File wrapping a syscall.Socketpair fd does not support deadlines and
+// a blocking Read on it can't be interrupted by closing the file from
+// another goroutine. The child's end is returned as a plain *os.File,
+// ready to hand off via os/exec.Cmd.ExtraFiles.
+func socketpair() (*net.UnixConn, *os.File, error) {
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	parentFile := os.NewFile(uintptr(fds[0]), "readiness-parent")
+	childFile := os.NewFile(uintptr(fds[1]), "readiness-child")
+
+	conn, err := net.FileConn(parentFile)
+	_ = parentFile.Close() // FileConn dups the fd; release our copy.
+	if err != nil {
+		_ = childFile.Close()
+		return nil, nil, err
+	}
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		_ = conn.Close()
+		_ = childFile.Close()
+		return nil, nil, fmt.Errorf("quit: readiness socket is not a *net.UnixConn")
+	}
+	return unixConn, childFile, nil
+}
+
+// waitForReadyTimeout blocks until a readiness byte arrives on conn, or
+// timeout elapses without one.
+func waitForReadyTimeout(conn *net.UnixConn, timeout time.Duration) error {
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 1)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return err
+	}
+	if n != 1 {
+		return fmt.Errorf("quit: short read waiting for readiness byte")
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+//go:build !windows
+
+package quit
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReadinessHandshakeRoundTrip(t *testing.T) {
+	parent, child, err := socketpair()
+	if err != nil {
+		t.Fatalf("socketpair: %v", err)
+	}
+	defer parent.Close()
+
+	if err := os.Setenv(envReadyFD, strconv.Itoa(int(child.Fd()))); err != nil {
+		t.Fatalf("Setenv: %v", err)
+	}
+	defer os.Unsetenv(envReadyFD)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- waitForReadyTimeout(parent, time.Second)
+	}()
+
+	// SignalReady reads envReadyFD and writes the readiness byte there,
+	// exactly as a forked child would once it's ready to serve.
+	if err := SignalReady(); err != nil {
+		t.Fatalf("SignalReady: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("waitForReadyTimeout returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waitForReadyTimeout did not return after SignalReady")
+	}
+}
+
+func TestWaitForReadyTimesOutWithoutASignal(t *testing.T) {
+	parent, child, err := socketpair()
+	if err != nil {
+		t.Fatalf("socketpair: %v", err)
+	}
+	defer parent.Close()
+	defer child.Close()
+
+	start := time.Now()
+	if err := waitForReadyTimeout(parent, 50*time.Millisecond); err == nil {
+		t.Fatal("expected an error when no readiness byte arrives before the timeout")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("waitForReadyTimeout took too long to give up: %v", elapsed)
+	}
+}
+
+// fdOpen reports whether fd refers to an already-open file descriptor in
+// this process, without going through os.File (which would register a
+// finalizer that could close someone else's fd out from under them).
+func fdOpen(fd int) bool {
+	var stat syscall.Stat_t
+	return syscall.Fstat(fd, &stat) == nil
+}
+
+func TestListenTCPReconstructsInheritedFD(t *testing.T) {
+	const targetFD = listenerFDOffset
+
+	if fdOpen(targetFD) {
+		t.Skipf("fd %d already in use by the test process, skipping fd-inheritance test", targetFD)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	addr := l.Addr().String()
+
+	tcpListener, ok := l.(*net.TCPListener)
+	if !ok {
+		t.Fatalf("listener is not a *net.TCPListener")
+	}
+	lf, err := tcpListener.File()
+	if err != nil {
+		t.Fatalf("File: %v", err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("closing original listener: %v", err)
+	}
+
+	// Simulate what Fork's os/exec.Cmd.ExtraFiles does for a real child
+	// process: place the dup'd listener fd at exactly the offset ListenTCP
+	// expects to find inherited listeners at.
+	if err := syscall.Dup2(int(lf.Fd()), targetFD); err != nil {
+		t.Fatalf("dup2: %v", err)
+	}
+	_ = lf.Close()
+	defer syscall.Close(targetFD)
+
+	if err := os.Setenv(EnvGoutilsListenFDs, "1"); err != nil {
+		t.Fatalf("Setenv: %v", err)
+	}
+	defer os.Unsetenv(EnvGoutilsListenFDs)
+
+	r := NewRestarter()
+	inherited, err := r.ListenTCP("tcp", addr)
+	if err != nil {
+		t.Fatalf("ListenTCP did not reconstruct the inherited listener: %v", err)
+	}
+	defer inherited.Close()
+
+	if got := inherited.Addr().String(); got != addr {
+		t.Fatalf("inherited listener address = %s, want %s", got, addr)
+	}
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial inherited listener: %v", err)
+	}
+	_ = conn.Close()
+}
@@ -0,0 +1,87 @@
+//go:build windows
+
+package quit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// DefaultRestartGracePeriod is the grace period Fork passes to GracefulStop
+// once the replacement process has started.
+const DefaultRestartGracePeriod = 30 * time.Second
+
+// Restarter supports zero-downtime binary upgrades. Windows has no
+// portable way to pass open sockets to a child process, so unlike the Unix
+// implementation this does not inherit listener file descriptors: it
+// starts a fresh instance of the binary and then gracefully stops this
+// one, accepting a brief overlap between the two processes instead. Fork
+// itself does not listen for any signal; call it directly on whatever
+// trigger the caller prefers. WatchHangup is provided only so callers can
+// share startup code with the Unix build; Windows has no SIGHUP to watch
+// for, so it does nothing.
+type Restarter struct {
+	mu        sync.Mutex
+	listeners []*net.TCPListener
+}
+
+// NewRestarter returns a ready-to-use Restarter.
+func NewRestarter() *Restarter {
+	return &Restarter{}
+}
+
+// WatchHangup is a no-op on Windows: there is no SIGHUP to listen for, so
+// restarts must be triggered by calling r.Fork directly.
+func WatchHangup(r *Restarter) {}
+
+// ListenTCP returns a TCP listener for addr. On Windows this always binds a
+// fresh socket; it exists so callers can use the same API as the Unix
+// build, which reconstructs listeners from inherited file descriptors.
+func (r *Restarter) ListenTCP(network, addr string) (*net.TCPListener, error) {
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	tcpListener, ok := l.(*net.TCPListener)
+	if !ok {
+		_ = l.Close()
+		return nil, fmt.Errorf("quit: %s listener is not a *net.TCPListener", network)
+	}
+	r.mu.Lock()
+	r.listeners = append(r.listeners, tcpListener)
+	r.mu.Unlock()
+	return tcpListener, nil
+}
+
+// Fork starts a fresh instance of the current binary and gracefully stops
+// this one. It does not pass listener file descriptors to the new
+// instance, so the two processes briefly overlap while the new one binds
+// its own sockets instead of sharing this process's.
+func (r *Restarter) Fork() error {
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("quit: resolve executable path: %w", err)
+	}
+
+	cmd := exec.Command(execPath, os.Args[1:]...)
+	cmd.Env = os.Environ()
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("quit: start replacement process: %w", err)
+	}
+
+	return GetQuitEvent().GracefulStop(context.Background(), DefaultRestartGracePeriod)
+}
+
+// SignalReady is a no-op on Windows: Fork does not wait for a readiness
+// handshake since there are no inherited listeners to hand off.
+func SignalReady() error {
+	return nil
+}